@@ -0,0 +1,33 @@
+package gowebdav
+
+import (
+	"encoding/base64"
+	"net/http"
+)
+
+// BasicAuth structure holds our credentials.
+type BasicAuth struct {
+	user string
+	pw   string
+}
+
+// Type identifies the BasicAuthenticator.
+func (b *BasicAuth) Type() string {
+	return "BasicAuth"
+}
+
+// User holds the BasicAuth username.
+func (b *BasicAuth) User() string {
+	return b.user
+}
+
+// Pass holds the BasicAuth password.
+func (b *BasicAuth) Pass() string {
+	return b.pw
+}
+
+// Authorize the current request.
+func (b *BasicAuth) Authorize(rq *http.Request, method string, path string) {
+	token := base64.StdEncoding.EncodeToString([]byte(b.user + ":" + b.pw))
+	rq.Header.Set("Authorization", "Basic "+token)
+}
@@ -2,6 +2,7 @@ package gowebdav
 
 import (
 	"fmt"
+	"net/http"
 )
 
 // BearerAuth structure holds our JWT Bearer Token
@@ -25,6 +26,6 @@ func (b *BearerAuth) Pass() string {
 }
 
 // Authorize the current request
-func (b *BearerAuth) Authorize(c *Client, method string, path string) {
-	c.headers.Set("Authorization", fmt.Sprintf("Bearer %s", b.pw))
+func (b *BearerAuth) Authorize(rq *http.Request, method string, path string) {
+	rq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", b.pw))
 }
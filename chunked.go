@@ -0,0 +1,262 @@
+package gowebdav
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ChunkOptions configures Client.WriteStreamChunked.
+type ChunkOptions struct {
+	// UserHint is the username segment of the Nextcloud uploads
+	// namespace ("/remote.php/dav/uploads/<UserHint>/..."). Defaults to
+	// the Client's own username.
+	UserHint string
+	// UploadID identifies the temporary upload collection, allowing a
+	// later call to resume it. A random id is generated when empty.
+	UploadID string
+	// Concurrency bounds how many chunk PUTs may be in flight at once.
+	// Chunks are still read off r in order (a single io.Reader can't be
+	// split across goroutines), but once read they are uploaded by a
+	// pool of this many workers. Defaults to 1 (fully sequential).
+	Concurrency int
+	// OnProgress, if set, is called after each chunk is confirmed
+	// uploaded with the cumulative bytes sent and the total size (-1 if
+	// unknown, e.g. reading from a non-seekable stream).
+	OnProgress func(sent, total int64)
+}
+
+// WriteStreamChunked uploads r to path in chunkSize-sized pieces using the
+// Nextcloud chunked-upload V2 protocol:
+// https://docs.nextcloud.com/server/latest/developer_manual/client_apis/WebDAV/chunking.html
+//
+// It resumes an interrupted upload sharing the same UploadID by listing
+// the temporary collection and skipping chunks already present, retries
+// each chunk PUT with exponential backoff, and falls back to a plain
+// WriteStream when the server has no /uploads namespace (404/501 on the
+// initial MKCOL).
+func (c *Client) WriteStreamChunked(p string, r io.Reader, chunkSize int64, mode os.FileMode, opts ChunkOptions) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("gowebdav: chunkSize must be > 0")
+	}
+
+	rs, canSeek := r.(io.ReadSeeker)
+	var total int64 = -1
+	if canSeek {
+		if n, err := rs.Seek(0, io.SeekEnd); err == nil {
+			total = n
+			_, _ = rs.Seek(0, io.SeekStart)
+		}
+	}
+
+	user := opts.UserHint
+	if user == "" {
+		user = c.auth.User()
+	}
+	uploadID := opts.UploadID
+	if uploadID == "" {
+		uploadID = newUploadID()
+	}
+	uploadDir := path.Join("/remote.php/dav/uploads", user, uploadID)
+
+	if err := c.Mkdir(uploadDir, mode); err != nil {
+		if se, ok := err.(*StatusError); ok && (se.Status == http.StatusNotFound || se.Status == http.StatusNotImplemented) {
+			return c.WriteStream(p, r, mode)
+		}
+		if se, ok := err.(*StatusError); !ok || se.Status != http.StatusMethodNotAllowed {
+			return err
+		}
+		// MethodNotAllowed: the collection already exists, resuming.
+	}
+
+	uploaded, err := c.listUploadedChunks(uploadDir)
+	if err != nil {
+		return err
+	}
+
+	var sent int64
+	for _, sz := range uploaded {
+		sent += sz
+	}
+	if opts.OnProgress != nil && sent > 0 {
+		opts.OnProgress(sent, total)
+	}
+
+	if err := c.uploadChunks(uploadDir, r, chunkSize, uploaded, total, &sent, opts); err != nil {
+		return err
+	}
+
+	rsp, err := c.req("MOVE", path.Join(uploadDir, ".file"), nil, func(req *http.Request) {
+		req.Header.Set("Destination", c.root+p)
+		req.Header.Set("Overwrite", "T")
+		if total >= 0 {
+			req.Header.Set("OC-Total-Length", strconv.FormatInt(total, 10))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 400 {
+		return &StatusError{Status: rsp.StatusCode}
+	}
+	return nil
+}
+
+type chunkJob struct {
+	idx  int64
+	data []byte
+}
+
+// uploadChunks reads r sequentially in chunkSize pieces, skipping indexes
+// already present in uploaded, and hands each new piece to a pool of
+// opts.Concurrency workers that PUT it (with retry) and report progress.
+func (c *Client) uploadChunks(uploadDir string, r io.Reader, chunkSize int64, uploaded map[int64]int64, total int64, sent *int64, opts ChunkOptions) error {
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan chunkJob)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	setErr := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				name := fmt.Sprintf("%08d", job.idx)
+				if err := c.putChunkWithRetry(path.Join(uploadDir, name), job.data, total); err != nil {
+					setErr(err)
+					continue
+				}
+				mu.Lock()
+				*sent += int64(len(job.data))
+				cur := *sent
+				mu.Unlock()
+				if opts.OnProgress != nil {
+					opts.OnProgress(cur, total)
+				}
+			}
+		}()
+	}
+
+	for idx := int64(1); ; idx++ {
+		if sz, ok := uploaded[idx]; ok {
+			if _, err := io.CopyN(io.Discard, r, sz); err != nil && err != io.EOF {
+				break
+			}
+			continue
+		}
+
+		mu.Lock()
+		aborting := firstErr != nil
+		mu.Unlock()
+		if aborting {
+			break
+		}
+
+		buf := make([]byte, chunkSize)
+		n, rerr := io.ReadFull(r, buf)
+		if n == 0 && (rerr == io.EOF || rerr == io.ErrUnexpectedEOF) {
+			break
+		}
+		if rerr != nil && rerr != io.EOF && rerr != io.ErrUnexpectedEOF {
+			setErr(rerr)
+			break
+		}
+
+		jobs <- chunkJob{idx: idx, data: buf[:n]}
+
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
+
+func (c *Client) putChunkWithRetry(chunkPath string, chunk []byte, total int64) error {
+	var lastErr error
+	backoff := 200 * time.Millisecond
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		rsp, err := c.req("PUT", chunkPath, bytes.NewReader(chunk), func(req *http.Request) {
+			req.ContentLength = int64(len(chunk))
+			if total >= 0 {
+				req.Header.Set("OC-Total-Length", strconv.FormatInt(total, 10))
+			}
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode >= 400 {
+			lastErr = &StatusError{Status: rsp.StatusCode}
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("gowebdav: chunk %s failed after retries: %w", chunkPath, lastErr)
+}
+
+// listUploadedChunks PROPFINDs the temporary upload collection and
+// returns the size of every already-uploaded chunk keyed by its index, so
+// a resumed upload can skip them.
+func (c *Client) listUploadedChunks(uploadDir string) (map[int64]int64, error) {
+	files, err := c.ReadDir(uploadDir)
+	if err != nil {
+		if se, ok := err.(*StatusError); ok && se.Status == http.StatusNotFound {
+			return map[int64]int64{}, nil
+		}
+		return nil, err
+	}
+
+	out := make(map[int64]int64, len(files))
+	for _, fi := range files {
+		name := fi.Name()
+		if name == ".file" {
+			continue
+		}
+		idx, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		out[idx] = fi.Size()
+	}
+	return out, nil
+}
+
+func newUploadID() string {
+	const hex = "0123456789abcdef"
+	b := make([]byte, 32)
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := range b {
+		b[i] = hex[rnd.Intn(len(hex))]
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s", b[0:8], b[8:12], b[12:16], b[16:20], b[20:32])
+}
@@ -0,0 +1,273 @@
+package gowebdav
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// nextcloudChunkServer is a minimal stand-in for the server side of the
+// Nextcloud chunked-upload V2 protocol: plain x/net/webdav has no notion
+// of a virtual ".file" that MOVE can assemble from previously PUT
+// chunks, so WriteStreamChunked's happy path can't be exercised against
+// it. This fixture tracks just enough directory/file state to support
+// MKCOL, PUT, PROPFIND (depth 1, size and a fixed modtime) and a MOVE of
+// ".file" that concatenates the numbered chunks it finds in chunk-number
+// order.
+type nextcloudChunkServer struct {
+	mu    sync.Mutex
+	dirs  map[string]bool
+	files map[string][]byte
+}
+
+func newNextcloudChunkServer() *nextcloudChunkServer {
+	return &nextcloudChunkServer{dirs: map[string]bool{"/": true}, files: map[string][]byte{}}
+}
+
+func (s *nextcloudChunkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := r.URL.Path
+	switch r.Method {
+	case "MKCOL":
+		parent := path.Dir(strings.TrimSuffix(p, "/"))
+		if !s.dirs[parent] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if s.dirs[p] {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		s.dirs[p] = true
+		w.WriteHeader(http.StatusCreated)
+
+	case "PUT":
+		var buf bytes.Buffer
+		buf.ReadFrom(r.Body)
+		s.files[p] = buf.Bytes()
+		w.WriteHeader(http.StatusCreated)
+
+	case "PROPFIND":
+		if !s.dirs[p] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0"?><D:multistatus xmlns:D="DAV:">`)
+		prefix := strings.TrimSuffix(p, "/") + "/"
+		for name, data := range s.files {
+			if !strings.HasPrefix(name, prefix) || strings.Contains(strings.TrimPrefix(name, prefix), "/") {
+				continue
+			}
+			fmt.Fprintf(&b, `<D:response><D:href>%s</D:href><D:propstat><D:prop><D:getcontentlength>%d</D:getcontentlength><D:getlastmodified>%s</D:getlastmodified></D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat></D:response>`,
+				name, len(data), "Mon, 01 Jan 2024 00:00:00 GMT")
+		}
+		b.WriteString(`</D:multistatus>`)
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(b.String()))
+
+	case "MOVE":
+		if !strings.HasSuffix(p, "/.file") {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		dir := strings.TrimSuffix(p, "/.file")
+		prefix := dir + "/"
+		var names []string
+		for name := range s.files {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			ai, _ := strconv.Atoi(path.Base(names[i]))
+			aj, _ := strconv.Atoi(path.Base(names[j]))
+			return ai < aj
+		})
+		var assembled bytes.Buffer
+		for _, name := range names {
+			assembled.Write(s.files[name])
+		}
+
+		dest := r.Header.Get("Destination")
+		u, err := parseDestinationPath(dest)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.files[u] = assembled.Bytes()
+		w.WriteHeader(http.StatusCreated)
+
+	case "GET":
+		data, ok := s.files[p]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func parseDestinationPath(dest string) (string, error) {
+	i := strings.Index(dest, "://")
+	if i < 0 {
+		return dest, nil
+	}
+	rest := dest[i+3:]
+	if j := strings.IndexByte(rest, '/'); j >= 0 {
+		return rest[j:], nil
+	}
+	return "", fmt.Errorf("no path in %q", dest)
+}
+
+func newChunkedTestClient(t *testing.T) *Client {
+	t.Helper()
+	srv := httptest.NewServer(newNextcloudChunkServer())
+	t.Cleanup(srv.Close)
+
+	c := NewClient(srv.URL, "tester", "")
+	if err := c.MkdirAll(path.Join("/remote.php/dav/uploads", c.auth.User()), 0755); err != nil {
+		t.Fatalf("MkdirAll uploads namespace: %v", err)
+	}
+	return c
+}
+
+func TestWriteStreamChunkedUploadsInOrder(t *testing.T) {
+	c := newChunkedTestClient(t)
+
+	want := strings.Repeat("0123456789", 250) // 2500 bytes, 5 chunks of 500
+	err := c.WriteStreamChunked("/big.txt", strings.NewReader(want), 500, 0644, ChunkOptions{UploadID: "order"})
+	if err != nil {
+		t.Fatalf("WriteStreamChunked: %v", err)
+	}
+
+	got, err := c.Read("/big.txt")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("content mismatch: got %d bytes, want %d", len(got), len(want))
+	}
+}
+
+func TestWriteStreamChunkedResumeSkipsUploadedChunks(t *testing.T) {
+	c := newChunkedTestClient(t)
+
+	want := strings.Repeat("A", 300) + strings.Repeat("B", 300) + strings.Repeat("C", 100)
+	uploadDir := path.Join("/remote.php/dav/uploads", c.auth.User(), "resume")
+	if err := c.Mkdir(uploadDir, 0755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	// Pre-upload chunk 1 by hand, as a previous interrupted run would
+	// have left it.
+	if err := c.Write(path.Join(uploadDir, "00000001"), []byte(strings.Repeat("A", 300)), 0644); err != nil {
+		t.Fatalf("seed chunk 1: %v", err)
+	}
+
+	var progressCalls []int64
+	var mu sync.Mutex
+	opts := ChunkOptions{
+		UploadID: "resume",
+		OnProgress: func(sent, total int64) {
+			mu.Lock()
+			progressCalls = append(progressCalls, sent)
+			mu.Unlock()
+		},
+	}
+	if err := c.WriteStreamChunked("/resumed.txt", strings.NewReader(want), 300, 0644, opts); err != nil {
+		t.Fatalf("WriteStreamChunked: %v", err)
+	}
+
+	got, err := c.Read("/resumed.txt")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("content mismatch after resume: got %q", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progressCalls) == 0 || progressCalls[0] < 300 {
+		t.Fatalf("expected the first progress report to already count the pre-uploaded chunk, got %v", progressCalls)
+	}
+}
+
+func TestWriteStreamChunkedFallsBackWhenUploadsNamespaceMissing(t *testing.T) {
+	inner := newNextcloudChunkServer()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "MKCOL" && strings.Contains(r.URL.Path, "/remote.php/dav/uploads/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "tester", "")
+	want := []byte("no chunked uploads here")
+	if err := c.WriteStreamChunked("/plain.txt", bytes.NewReader(want), 4, 0644, ChunkOptions{}); err != nil {
+		t.Fatalf("WriteStreamChunked: %v", err)
+	}
+
+	got, err := c.Read("/plain.txt")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("content mismatch: got %q, want %q", got, want)
+	}
+}
+
+func TestPutChunkWithRetryRecoversFromTransientFailures(t *testing.T) {
+	var failuresLeft int32 = 2
+	inner := newNextcloudChunkServer()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" && failuresLeft > 0 {
+			failuresLeft--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "tester", "")
+	if err := c.putChunkWithRetry("/flaky-chunk", []byte("chunk-data"), -1); err != nil {
+		t.Fatalf("putChunkWithRetry should recover from transient 500s, got: %v", err)
+	}
+	if failuresLeft != 0 {
+		t.Fatalf("expected both injected failures to be consumed, %d left", failuresLeft)
+	}
+}
+
+func TestWriteStreamChunkedConcurrentWorkersProduceCorrectContent(t *testing.T) {
+	c := newChunkedTestClient(t)
+
+	want := strings.Repeat("x", 50) + strings.Repeat("y", 50) + strings.Repeat("z", 50) + strings.Repeat("w", 37)
+	opts := ChunkOptions{UploadID: "concurrent", Concurrency: 4}
+	if err := c.WriteStreamChunked("/concurrent.txt", strings.NewReader(want), 50, 0644, opts); err != nil {
+		t.Fatalf("WriteStreamChunked: %v", err)
+	}
+
+	got, err := c.Read("/concurrent.txt")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("content mismatch with Concurrency > 1: got %q", got)
+	}
+}
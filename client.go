@@ -0,0 +1,351 @@
+// Package gowebdav is a WebDAV client that lets you perform the basic
+// operations (LS/STAT/GET/PUT/...) against a WebDAV endpoint, as a
+// library or through cmd/gowebdav.
+package gowebdav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator authorizes outgoing requests for a Client. BasicAuth and
+// BearerAuth both implement it; a Client gets one from NewClient or
+// NewClientJWT and has no method to replace it.
+type Authenticator interface {
+	// Type identifies the authenticator, e.g. "BasicAuth".
+	Type() string
+	// User holds the authenticating username.
+	User() string
+	// Pass holds the authenticating secret.
+	Pass() string
+	// Authorize sets whatever headers are needed on the outgoing request
+	// rq for the given method/path.
+	Authorize(rq *http.Request, method string, path string)
+}
+
+// Client defines our structure for talking to a WebDAV endpoint.
+type Client struct {
+	root    string
+	headers http.Header
+	c       *http.Client
+
+	authMutex sync.Mutex
+	auth      Authenticator
+
+	lockSystem LockSystem
+	compliance DavCompliance
+}
+
+// NewClient creates a new Client pointed at root, authorized with HTTP
+// Basic auth.
+func NewClient(root, user, pw string) *Client {
+	return &Client{
+		root:    strings.TrimSuffix(root, "/"),
+		headers: make(http.Header),
+		c:       &http.Client{},
+		auth:    &BasicAuth{user: user, pw: pw},
+	}
+}
+
+// NewClientJWT creates a new Client pointed at root, authorized with a JWT
+// bearer token and using httpClient as its transport (e.g. to relax TLS
+// verification).
+func NewClientJWT(root, token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &Client{
+		root:    strings.TrimSuffix(root, "/"),
+		headers: make(http.Header),
+		c:       httpClient,
+		auth:    &BearerAuth{pw: token},
+	}
+}
+
+// SetHeader sets an additional header sent with every request, e.g. a
+// custom User-Agent.
+func (c *Client) SetHeader(key, value string) {
+	c.headers.Set(key, value)
+}
+
+// SetTransport overrides the *http.Transport used for outgoing requests.
+func (c *Client) SetTransport(t http.RoundTripper) {
+	c.c.Transport = t
+}
+
+func mutatingMethod(method string) bool {
+	switch method {
+	case "PUT", "MOVE", "DELETE", "PROPPATCH", "MKCOL":
+		return true
+	}
+	return false
+}
+
+// req builds and sends an HTTP request against path, authorizing it and -
+// for PUT/MOVE/DELETE/PROPPATCH/MKCOL - automatically attaching an "If"
+// header when path is currently locked in c.lockSystem.
+func (c *Client) req(method, path string, body io.Reader, intercept func(*http.Request)) (*http.Response, error) {
+	rq, err := http.NewRequest(method, c.root+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, vs := range c.headers {
+		for _, v := range vs {
+			rq.Header.Add(k, v)
+		}
+	}
+
+	c.authMutex.Lock()
+	if c.auth != nil {
+		c.auth.Authorize(rq, method, path)
+	}
+	c.authMutex.Unlock()
+
+	if mutatingMethod(method) {
+		if h := c.ifHeaderFor(path); h != "" {
+			rq.Header.Set("If", h)
+		}
+	}
+
+	if intercept != nil {
+		intercept(rq)
+	}
+
+	return c.c.Do(rq)
+}
+
+const defaultPropfindBody = `<?xml version="1.0"?>` +
+	`<D:propfind xmlns:D="DAV:">` +
+	`<D:prop>` +
+	`<D:displayname/><D:getcontentlength/><D:getlastmodified/><D:getetag/><D:resourcetype/>` +
+	`</D:prop></D:propfind>`
+
+type propfindResponseXML struct {
+	Response []struct {
+		Href     string `xml:"href"`
+		PropStat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				DisplayName   string `xml:"displayname"`
+				ContentLength string `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+				ETag          string `xml:"getetag"`
+				ResourceType  struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+func (c *Client) propfind(path, depth string) ([]*File, error) {
+	rs, err := c.req("PROPFIND", path, strings.NewReader(defaultPropfindBody), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/xml;charset=UTF-8")
+		r.Header.Set("Depth", depth)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return nil, &StatusError{Status: rs.StatusCode}
+	}
+
+	var ms propfindResponseXML
+	if err := xml.NewDecoder(rs.Body).Decode(&ms); err != nil {
+		return nil, err
+	}
+
+	files := make([]*File, 0, len(ms.Response))
+	for _, resp := range ms.Response {
+		if len(resp.PropStat) == 0 || !strings.Contains(resp.PropStat[0].Status, "200") {
+			continue
+		}
+		prop := resp.PropStat[0].Prop
+
+		href := resp.Href
+		if unescaped, err := url.QueryUnescape(href); err == nil {
+			href = unescaped
+		}
+		trimmed := strings.TrimSuffix(href, "/")
+		name := trimmed
+		if i := strings.LastIndex(trimmed, "/"); i >= 0 {
+			name = trimmed[i+1:]
+		}
+
+		size, _ := strconv.ParseInt(prop.ContentLength, 10, 64)
+		modified, _ := time.Parse(time.RFC1123, prop.LastModified)
+
+		files = append(files, &File{
+			name:     name,
+			path:     href,
+			size:     size,
+			modified: modified,
+			isdir:    prop.ResourceType.Collection != nil,
+			etag:     strings.Trim(prop.ETag, `"`),
+		})
+	}
+	return files, nil
+}
+
+func normalizePath(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+// ReadDir lists the children of path.
+func (c *Client) ReadDir(path string) ([]os.FileInfo, error) {
+	files, err := c.propfind(path, "1")
+	if err != nil {
+		return nil, err
+	}
+
+	self := strings.TrimSuffix(normalizePath(path), "/")
+	out := make([]os.FileInfo, 0, len(files))
+	for _, f := range files {
+		if strings.TrimSuffix(normalizePath(f.path), "/") == self {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// Stat returns the FileInfo for path.
+func (c *Client) Stat(path string) (os.FileInfo, error) {
+	files, err := c.propfind(path, "0")
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, &StatusError{Status: http.StatusNotFound}
+	}
+	return files[0], nil
+}
+
+// ReadStream opens path for reading. The caller must Close it.
+func (c *Client) ReadStream(path string) (io.ReadCloser, error) {
+	rs, err := c.req("GET", path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if rs.StatusCode >= 400 {
+		rs.Body.Close()
+		return nil, &StatusError{Status: rs.StatusCode}
+	}
+	return rs.Body, nil
+}
+
+// Read returns the full contents of path.
+func (c *Client) Read(path string) ([]byte, error) {
+	rc, err := c.ReadStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// WriteStream uploads the contents of r to path.
+func (c *Client) WriteStream(path string, r io.Reader, _ os.FileMode) error {
+	rs, err := c.req("PUT", path, r, nil)
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+	if rs.StatusCode >= 400 {
+		return &StatusError{Status: rs.StatusCode}
+	}
+	return nil
+}
+
+// Write uploads data to path.
+func (c *Client) Write(path string, data []byte, mode os.FileMode) error {
+	return c.WriteStream(path, bytes.NewReader(data), mode)
+}
+
+// Remove deletes path. It is not an error for path to already be gone.
+func (c *Client) Remove(path string) error {
+	rs, err := c.req("DELETE", path, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+	if rs.StatusCode >= 400 && rs.StatusCode != http.StatusNotFound {
+		return &StatusError{Status: rs.StatusCode}
+	}
+	return nil
+}
+
+// Mkdir creates the single collection path.
+func (c *Client) Mkdir(path string, _ os.FileMode) error {
+	rs, err := c.req("MKCOL", path, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+	switch rs.StatusCode {
+	case http.StatusCreated, http.StatusMethodNotAllowed:
+		return nil
+	}
+	return &StatusError{Status: rs.StatusCode}
+}
+
+// MkdirAll creates path and any missing parents.
+func (c *Client) MkdirAll(path string, mode os.FileMode) error {
+	parts := strings.Split(strings.Trim(normalizePath(path), "/"), "/")
+	cur := ""
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		cur += "/" + part
+		if err := c.Mkdir(cur, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) copyMove(method, oldpath, newpath string, overwrite bool) error {
+	rs, err := c.req(method, oldpath, nil, func(r *http.Request) {
+		r.Header.Set("Destination", c.root+normalizePath(newpath))
+		if overwrite {
+			r.Header.Set("Overwrite", "T")
+		} else {
+			r.Header.Set("Overwrite", "F")
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+	if rs.StatusCode >= 400 {
+		return &StatusError{Status: rs.StatusCode}
+	}
+	return nil
+}
+
+// Rename moves oldpath to newpath, optionally overwriting an existing
+// file at newpath.
+func (c *Client) Rename(oldpath, newpath string, overwrite bool) error {
+	return c.copyMove("MOVE", oldpath, newpath, overwrite)
+}
+
+// Copy duplicates oldpath to newpath, optionally overwriting an existing
+// file at newpath.
+func (c *Client) Copy(oldpath, newpath string, overwrite bool) error {
+	return c.copyMove("COPY", oldpath, newpath, overwrite)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"flag"
@@ -13,6 +14,7 @@ import (
 	"strings"
 
 	d "github.com/mcmap4/gowebdav"
+	"github.com/mcmap4/gowebdav/sync"
 )
 
 func main() {
@@ -21,6 +23,13 @@ func main() {
 	pw := flag.String("pw", os.Getenv("PASSWORD"), "Password [ENV.PASSWORD]")
 	token := flag.String("token", os.Getenv("TOKEN"), "JWT Token [ENV.TOKEN]")
 	netrc := flag.String("netrc-file", filepath.Join(getHome(), ".netrc"), "read login from netrc file")
+	serve := flag.Bool("serve", false, "run as a WebDAV server instead of a client")
+	listen := flag.String("listen", ":8080", "address to listen on in -serve mode")
+	serveDir := flag.String("serve-dir", "", "local directory to export in -serve mode (defaults to an in-memory filesystem)")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file for -serve mode")
+	tlsKey := flag.String("tls-key", "", "TLS key file for -serve mode")
+	htpasswd := flag.String("htpasswd", "", "htpasswd file for basic auth in -serve mode (bcrypt hashes only - generate with `htpasswd -B`)")
+	bearer := flag.String("bearer", os.Getenv("TOKEN"), "bearer token accepted in -serve mode [ENV.TOKEN]")
 	method := flag.String("X", "", `Method:
 	LS <PATH>
 	STAT <PATH>
@@ -35,9 +44,36 @@ func main() {
 	CP <OLD> <NEW>
 
 	DEL <PATH>
+
+	LOCK <PATH>
+	UNLOCK <PATH> <TOKEN>
+
+	SYNC <LOCAL> <REMOTE>
+	MIRROR <LOCAL> <REMOTE>
+
+	PUTCHUNKED <PATH> [<FILE>]
+
+	CAPS
+	WHOAMI
+
+	SERVE
 	`)
 	flag.Parse()
 
+	if *serve || strings.ToUpper(*method) == "SERVE" {
+		if e := runServe(serveOptions{
+			Listen:   *listen,
+			Dir:      *serveDir,
+			TLSCert:  *tlsCert,
+			TLSKey:   *tlsKey,
+			Htpasswd: *htpasswd,
+			Bearer:   *bearer,
+		}); e != nil {
+			fail(e)
+		}
+		return
+	}
+
 	if *root == "" {
 		fail("Set WebDAV ROOT")
 	}
@@ -123,6 +159,27 @@ func getCmd(method string) func(c *d.Client, p0, p1 string) error {
 	case "PUT", "PUSH", "WRITE":
 		return cmdPut
 
+	case "LOCK":
+		return cmdLock
+
+	case "UNLOCK":
+		return cmdUnlock
+
+	case "SYNC":
+		return cmdSync
+
+	case "MIRROR":
+		return cmdMirror
+
+	case "PUTCHUNKED":
+		return cmdPutChunked
+
+	case "CAPS":
+		return cmdCaps
+
+	case "WHOAMI":
+		return cmdWhoami
+
 	default:
 		return func(c *d.Client, p0, p1 string) (err error) {
 			return errors.New("Unsupported method: " + method)
@@ -214,6 +271,79 @@ func cmdPut(c *d.Client, p0, p1 string) (err error) {
 	return
 }
 
+func cmdLock(c *d.Client, p0, _ string) (err error) {
+	token, err := c.Lock(p0, d.LockOptions{Scope: d.ExclusiveLock, Owner: "gowebdav-cli"})
+	if err == nil {
+		fmt.Println("Lock: " + p0 + " -> " + token.Token)
+	}
+	return
+}
+
+func cmdUnlock(c *d.Client, p0, p1 string) (err error) {
+	if err = c.Unlock(p0, d.LockToken{Token: p1, Root: p0}); err == nil {
+		fmt.Println("Unlock: " + p0)
+	}
+	return
+}
+
+func cmdSync(c *d.Client, p0, p1 string) (err error) {
+	s := sync.NewSyncer(c, p0, p1)
+	if err = s.Push(context.Background()); err == nil {
+		fmt.Println("Sync: " + p0 + " -> " + p1)
+	}
+	return
+}
+
+func cmdMirror(c *d.Client, p0, p1 string) (err error) {
+	s := sync.NewSyncer(c, p0, p1)
+	if err = s.Mirror(context.Background()); err == nil {
+		fmt.Println("Mirror: " + p0 + " <-> " + p1)
+	}
+	return
+}
+
+// defaultChunkSize is used by PUTCHUNKED; 10MiB matches Nextcloud's own
+// desktop client default.
+const defaultChunkSize = 10 << 20
+
+func cmdPutChunked(c *d.Client, p0, p1 string) (err error) {
+	if p1 == "" {
+		p1 = filepath.Join(".", p0)
+	}
+	stream, err := getStream(p1)
+	if err != nil {
+		return
+	}
+	defer stream.Close()
+
+	err = c.WriteStreamChunked(p0, stream, defaultChunkSize, 0644, d.ChunkOptions{
+		OnProgress: func(sent, total int64) {
+			fmt.Println(fmt.Sprintf("PutChunked: %s -> %s (%d/%d bytes)", p1, p0, sent, total))
+		},
+	})
+	if err == nil {
+		fmt.Println("PutChunked: " + p1 + " -> " + p0)
+	}
+	return
+}
+
+func cmdCaps(c *d.Client, _, _ string) (err error) {
+	dav, allow, err := c.Options("/")
+	if err == nil {
+		fmt.Println("DAV: " + strings.Join(dav, ", "))
+		fmt.Println("Allow: " + strings.Join(allow, ", "))
+	}
+	return
+}
+
+func cmdWhoami(c *d.Client, _, _ string) (err error) {
+	principal, err := c.FindCurrentUserPrincipal()
+	if err == nil {
+		fmt.Println(principal)
+	}
+	return
+}
+
 func writeFile(path string, bytes []byte, mode os.FileMode) error {
 	parent := filepath.Dir(path)
 	if _, e := os.Stat(parent); os.IsNotExist(e) {
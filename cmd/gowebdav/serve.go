@@ -0,0 +1,182 @@
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/webdav"
+)
+
+// serveOptions configures the embedded server started by runServe.
+type serveOptions struct {
+	Listen   string
+	Dir      string // empty means serve an in-memory filesystem
+	TLSCert  string
+	TLSKey   string
+	Htpasswd string
+	Bearer   string
+}
+
+// runServe turns the CLI into a WebDAV server, mirroring the design of
+// srvdav: golang.org/x/net/webdav does the protocol work, we add request
+// logging and the same auth plumbing the client side uses.
+func runServe(opts serveOptions) error {
+	var fs webdav.FileSystem
+	if opts.Dir != "" {
+		fs = webdav.Dir(opts.Dir)
+	} else {
+		fs = webdav.NewMemFS()
+	}
+
+	handler := &webdav.Handler{
+		FileSystem: fs,
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			logRequest(r, err)
+		},
+	}
+
+	var h http.Handler = handler
+	if opts.Htpasswd != "" || opts.Bearer != "" {
+		auth, err := newServeAuth(opts.Htpasswd, opts.Bearer)
+		if err != nil {
+			return err
+		}
+		h = auth.wrap(h)
+	}
+
+	srv := &http.Server{
+		Addr:    opts.Listen,
+		Handler: h,
+	}
+
+	if opts.TLSCert != "" || opts.TLSKey != "" {
+		srv.TLSConfig = &tls.Config{}
+		log.Printf("gowebdav: serving %s on https://%s", fsDescription(opts), opts.Listen)
+		return srv.ListenAndServeTLS(opts.TLSCert, opts.TLSKey)
+	}
+
+	log.Printf("gowebdav: serving %s on http://%s", fsDescription(opts), opts.Listen)
+	return srv.ListenAndServe()
+}
+
+func fsDescription(opts serveOptions) string {
+	if opts.Dir != "" {
+		return opts.Dir
+	}
+	return "an in-memory filesystem"
+}
+
+// logRequest prints one line per request in a format similar to srvdav's:
+// timestamp, remote, method, path, Destination, Overwrite, error.
+func logRequest(r *http.Request, err error) {
+	fields := []string{
+		time.Now().Format(time.RFC3339),
+		r.RemoteAddr,
+		r.Method,
+		r.URL.Path,
+		r.Header.Get("Destination"),
+		r.Header.Get("Overwrite"),
+	}
+	if err != nil {
+		fields = append(fields, err.Error())
+	}
+	fmt.Fprintln(os.Stderr, strings.Join(fields, " "))
+}
+
+// serveAuth checks requests against an htpasswd file (basic auth) and/or a
+// single static bearer token, reusing the same two schemes the client
+// side speaks.
+//
+// Only bcrypt hashes (the ones `htpasswd -B` produces) are supported -
+// apr1-MD5 and crypt entries from a plain `htpasswd` invocation are
+// rejected up front with a clear error instead of failing every login
+// silently.
+type serveAuth struct {
+	htpasswd map[string]string // user -> bcrypt hash
+	bearer   string
+}
+
+func newServeAuth(htpasswdFile, bearer string) (*serveAuth, error) {
+	a := &serveAuth{bearer: bearer}
+	if htpasswdFile != "" {
+		creds, err := parseHtpasswd(htpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+		a.htpasswd = creds
+	}
+	return a, nil
+}
+
+func parseHtpasswd(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string)
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		user, hash := parts[0], parts[1]
+		if !isBcryptHash(hash) {
+			return nil, fmt.Errorf("%s:%d: user %q has a non-bcrypt hash; regenerate the file with `htpasswd -B`", path, n+1, user)
+		}
+		out[user] = hash
+	}
+	return out, nil
+}
+
+// isBcryptHash reports whether hash looks like one of the bcrypt prefixes
+// htpasswd -B produces. apr1-MD5 ("$apr1$") and crypt (13 chars, no "$")
+// entries are deliberately rejected rather than silently mismatched.
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (a *serveAuth) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.bearer != "" {
+			if tok := bearerToken(r); tok != "" && subtle.ConstantTimeCompare([]byte(tok), []byte(a.bearer)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		if a.htpasswd != nil {
+			user, pass, ok := r.BasicAuth()
+			if ok {
+				if hash, found := a.htpasswd[user]; found && bcrypt.CompareHashAndPassword([]byte(hash), []byte(pass)) == nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+
+		w.Header().Set("WWW-Authenticate", `Basic realm="gowebdav"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
+
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if strings.HasPrefix(h, prefix) {
+		return strings.TrimPrefix(h, prefix)
+	}
+	return ""
+}
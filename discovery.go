@@ -0,0 +1,172 @@
+package gowebdav
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthenticated is returned by FindCurrentUserPrincipal when the
+// server responds with a <D:unauthenticated/> principal, meaning the
+// request carried no (or invalid) credentials.
+var ErrUnauthenticated = errors.New("gowebdav: unauthenticated")
+
+// DavCompliance is the set of compliance classes a server advertised in
+// its "DAV:" response header, e.g. "1", "2", "3", "extended-mkcol".
+type DavCompliance []string
+
+// Has reports whether class (e.g. "2" or "extended-mkcol") was
+// advertised.
+func (d DavCompliance) Has(class string) bool {
+	for _, c := range d {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Options issues an HTTP OPTIONS request against path and returns the
+// parsed "DAV:" compliance classes together with the methods listed in
+// "Allow:". The compliance classes are also cached on the Client and
+// returned by Compliance, so higher-level code can decide what's safe to
+// send.
+func (c *Client) Options(path string) (DavCompliance, []string, error) {
+	rs, err := c.req("OPTIONS", path, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return nil, nil, &StatusError{Status: rs.StatusCode}
+	}
+
+	dav := parseCommaHeader(rs.Header.Get("DAV"))
+	allow := parseCommaHeader(rs.Header.Get("Allow"))
+
+	c.compliance = dav
+
+	return dav, allow, nil
+}
+
+// Compliance returns the DavCompliance classes discovered by the most
+// recent call to Options, or nil if Options has not been called yet.
+func (c *Client) Compliance() DavCompliance {
+	return c.compliance
+}
+
+func parseCommaHeader(v string) []string {
+	if v == "" {
+		return nil
+	}
+	parts := strings.Split(v, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+type currentUserPrincipalXML struct {
+	Response []struct {
+		PropStat []struct {
+			Prop struct {
+				CurrentUserPrincipal struct {
+					Href            string    `xml:"href"`
+					Unauthenticated *struct{} `xml:"unauthenticated"`
+				} `xml:"current-user-principal"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// FindCurrentUserPrincipal does a depth-0 PROPFIND for
+// DAV:current-user-principal against "/" and returns its href. It returns
+// ErrUnauthenticated if the server reports the principal as
+// DAV:unauthenticated.
+func (c *Client) FindCurrentUserPrincipal() (string, error) {
+	body := `<D:propfind xmlns:D="DAV:"><D:prop><D:current-user-principal/></D:prop></D:propfind>`
+
+	rs, err := c.req("PROPFIND", "/", strings.NewReader(body), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/xml;charset=UTF-8")
+		r.Header.Set("Depth", "0")
+	})
+	if err != nil {
+		return "", err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return "", &StatusError{Status: rs.StatusCode}
+	}
+
+	var ms currentUserPrincipalXML
+	if err := xml.NewDecoder(rs.Body).Decode(&ms); err != nil {
+		return "", err
+	}
+
+	for _, resp := range ms.Response {
+		for _, ps := range resp.PropStat {
+			cup := ps.Prop.CurrentUserPrincipal
+			if cup.Unauthenticated != nil {
+				return "", ErrUnauthenticated
+			}
+			if cup.Href != "" {
+				return cup.Href, nil
+			}
+		}
+	}
+	return "", errors.New("gowebdav: no current-user-principal in response")
+}
+
+// FindHomeSet does a depth-0 PROPFIND for the "{ns}localName-home-set"
+// property against principal and returns the first href it contains. It
+// is the generic building block behind things like CalDAV's
+// calendar-home-set and CardDAV's addressbook-home-set.
+func (c *Client) FindHomeSet(principal, ns, localName string) (string, error) {
+	prop := localName + "-home-set"
+
+	body := `<D:propfind xmlns:D="DAV:" xmlns:H="` + ns + `"><D:prop><H:` + prop + `/></D:prop></D:propfind>`
+
+	rs, err := c.req("PROPFIND", principal, strings.NewReader(body), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/xml;charset=UTF-8")
+		r.Header.Set("Depth", "0")
+	})
+	if err != nil {
+		return "", err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return "", &StatusError{Status: rs.StatusCode}
+	}
+
+	var ms struct {
+		Response []struct {
+			PropStat []struct {
+				Prop struct {
+					HomeSet struct {
+						Href string `xml:"href"`
+					} `xml:",any"`
+				} `xml:"prop"`
+			} `xml:"propstat"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(rs.Body).Decode(&ms); err != nil {
+		return "", err
+	}
+
+	for _, resp := range ms.Response {
+		for _, ps := range resp.PropStat {
+			if ps.Prop.HomeSet.Href != "" {
+				return ps.Prop.HomeSet.Href, nil
+			}
+		}
+	}
+	return "", errors.New("gowebdav: no " + ns + prop + " in response")
+}
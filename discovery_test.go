@@ -0,0 +1,46 @@
+package gowebdav
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOptionsReturnsStatusErrorOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "")
+	_, _, err := c.Options("/")
+	if se, ok := err.(*StatusError); !ok || se.Status != http.StatusUnauthorized {
+		t.Fatalf("expected a 401 StatusError, got %v (%T)", err, err)
+	}
+}
+
+func TestFindCurrentUserPrincipalReturnsStatusErrorOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "")
+	_, err := c.FindCurrentUserPrincipal()
+	if se, ok := err.(*StatusError); !ok || se.Status != http.StatusUnauthorized {
+		t.Fatalf("expected a 401 StatusError, got %v (%T)", err, err)
+	}
+}
+
+func TestFindHomeSetReturnsStatusErrorOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "")
+	_, err := c.FindHomeSet("/principal", "urn:ietf:params:xml:ns:caldav", "calendar")
+	if se, ok := err.(*StatusError); !ok || se.Status != http.StatusInternalServerError {
+		t.Fatalf("expected a 500 StatusError, got %v (%T)", err, err)
+	}
+}
@@ -0,0 +1,25 @@
+package gowebdav
+
+import "fmt"
+
+// StatusError is returned whenever the server answers with an HTTP status
+// code callers need to branch on.
+type StatusError struct {
+	Status int
+}
+
+func (se *StatusError) Error() string {
+	return fmt.Sprintf("gowebdav: unexpected status %d", se.Status)
+}
+
+// IsErrCode reports whether err is a *StatusError carrying the given
+// status code.
+func IsErrCode(err error, code int) bool {
+	se, ok := err.(*StatusError)
+	return ok && se.Status == code
+}
+
+// IsErrNotFound reports whether err represents an HTTP 404 response.
+func IsErrNotFound(err error) bool {
+	return IsErrCode(err, 404)
+}
@@ -0,0 +1,46 @@
+package gowebdav
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// File is the os.FileInfo implementation returned by ReadDir and Stat.
+type File struct {
+	name     string
+	path     string
+	size     int64
+	modified time.Time
+	isdir    bool
+	etag     string
+}
+
+func (f *File) Name() string { return f.name }
+
+func (f *File) Size() int64 { return f.size }
+
+func (f *File) Mode() os.FileMode {
+	if f.isdir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (f *File) ModTime() time.Time { return f.modified }
+
+func (f *File) IsDir() bool { return f.isdir }
+
+func (f *File) Sys() interface{} { return nil }
+
+// ETag returns the server-reported getetag for this entry, or "" if the
+// server didn't send one. It lets callers (e.g. sync) detect content
+// changes more precisely than size/modtime alone.
+func (f *File) ETag() string { return f.etag }
+
+func (f *File) String() string {
+	if f.isdir {
+		return fmt.Sprintf("Dir : '%s'", f.path)
+	}
+	return fmt.Sprintf("File: '%s' Size: %d ETag: %s", f.path, f.size, f.etag)
+}
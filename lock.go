@@ -0,0 +1,275 @@
+package gowebdav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LockScope identifies the scope of a WebDAV lock, as defined by RFC 4918.
+type LockScope string
+
+const (
+	// ExclusiveLock requests or describes an exclusive write lock.
+	ExclusiveLock LockScope = "exclusive"
+	// SharedLock requests or describes a shared write lock.
+	SharedLock LockScope = "shared"
+)
+
+// LockOptions configures a call to Client.Lock.
+type LockOptions struct {
+	Scope   LockScope     // defaults to ExclusiveLock
+	Owner   string        // href or free-form text identifying the lock holder
+	Timeout time.Duration // requested timeout; 0 means "Infinite"
+}
+
+// LockToken is the opaque token returned by the server for a successful
+// LOCK request. It is passed back to Unlock and Refresh, and is what gets
+// sent in the "If" header of subsequent mutating requests.
+type LockToken struct {
+	Token   string        // the raw token, e.g. "opaquelocktoken:e71d4fae-..."
+	Root    string        // path the lock was issued for
+	Timeout time.Duration // timeout requested by Lock or Refresh, 0 means infinite; the server's activelock timeout is not read
+}
+
+// LockSystem is the extension point for storing active lock tokens so that
+// mutating requests (PUT, MOVE, DELETE, PROPPATCH, MKCOL) can automatically
+// carry the right "If" header. It mirrors the shape of
+// golang.org/x/net/webdav.LockSystem so a caller can bridge the two when
+// running gowebdav against a server backed by that package.
+type LockSystem interface {
+	// Put records the token that currently protects path.
+	Put(path string, token LockToken)
+	// Get returns the token protecting path, if any.
+	Get(path string) (LockToken, bool)
+	// Remove forgets the token protecting path.
+	Remove(path string)
+}
+
+// memLS is the default in-memory LockSystem. Lock installs one on a Client
+// that has no LockSystem yet (i.e. SetLockSystem was not called).
+type memLS struct {
+	mu     sync.Mutex
+	tokens map[string]LockToken
+}
+
+// NewMemLS creates an in-memory LockSystem suitable for a single process.
+func NewMemLS() LockSystem {
+	return &memLS{tokens: make(map[string]LockToken)}
+}
+
+func (m *memLS) Put(path string, token LockToken) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[path] = token
+}
+
+func (m *memLS) Get(path string) (LockToken, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[path]
+	return t, ok
+}
+
+func (m *memLS) Remove(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, path)
+}
+
+// SetLockSystem replaces the Client's LockSystem, e.g. to share tokens
+// across clients or to plug in a persistent store.
+func (c *Client) SetLockSystem(ls LockSystem) {
+	c.lockSystem = ls
+}
+
+// ifHeaderFor returns the "If" header value to send for path, if path (or
+// an ancestor of it) is currently locked. It is consulted by the mutating
+// request helpers in client.go before PUT, MOVE, DELETE, PROPPATCH and
+// MKCOL are issued.
+func (c *Client) ifHeaderFor(path string) string {
+	if c.lockSystem == nil {
+		return ""
+	}
+	for p := path; p != ""; p = parentOf(p) {
+		if tok, ok := c.lockSystem.Get(p); ok {
+			return fmt.Sprintf("(<%s>)", tok.Token)
+		}
+	}
+	return ""
+}
+
+func parentOf(path string) string {
+	i := len(path) - 1
+	for i >= 0 && path[i] == '/' {
+		i--
+	}
+	for i >= 0 && path[i] != '/' {
+		i--
+	}
+	if i < 0 {
+		return ""
+	}
+	return path[:i+1]
+}
+
+type lockInfoXML struct {
+	XMLName   xml.Name `xml:"D:lockinfo"`
+	Xmlns     string   `xml:"xmlns:D,attr"`
+	LockScope struct {
+		Exclusive *struct{} `xml:"D:exclusive,omitempty"`
+		Shared    *struct{} `xml:"D:shared,omitempty"`
+	} `xml:"D:lockscope"`
+	LockType struct {
+		Write struct{} `xml:"D:write"`
+	} `xml:"D:locktype"`
+	Owner struct {
+		Href string `xml:"D:href,omitempty"`
+	} `xml:"D:owner"`
+}
+
+type propMultistatus struct {
+	Response []struct {
+		PropStat []struct {
+			Prop struct {
+				LockDiscovery struct {
+					ActiveLock []struct {
+						Timeout   string `xml:"timeout"`
+						LockToken struct {
+							Href string `xml:"href"`
+						} `xml:"locktoken"`
+					} `xml:"activelock"`
+				} `xml:"lockdiscovery"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// Lock acquires a WebDAV lock on path and returns the token the server
+// issued. The lock (and any automatically-added "If" header on later
+// mutations of path) is tracked by the Client's LockSystem.
+func (c *Client) Lock(path string, opts LockOptions) (LockToken, error) {
+	scope := opts.Scope
+	if scope == "" {
+		scope = ExclusiveLock
+	}
+
+	body := lockInfoXML{Xmlns: "DAV:"}
+	if scope == SharedLock {
+		body.LockScope.Shared = &struct{}{}
+	} else {
+		body.LockScope.Exclusive = &struct{}{}
+	}
+	body.Owner.Href = opts.Owner
+
+	buf, err := xml.Marshal(body)
+	if err != nil {
+		return LockToken{}, err
+	}
+
+	var timeoutHeader string
+	if opts.Timeout > 0 {
+		timeoutHeader = fmt.Sprintf("Second-%d", int(opts.Timeout.Seconds()))
+	} else {
+		timeoutHeader = "Infinite"
+	}
+
+	rs, err := c.req("LOCK", path, bytes.NewReader(buf), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/xml;charset=UTF-8")
+		r.Header.Set("Depth", "0")
+		r.Header.Set("Timeout", timeoutHeader)
+	})
+	if err != nil {
+		return LockToken{}, err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return LockToken{}, &StatusError{Status: rs.StatusCode}
+	}
+
+	token := rs.Header.Get("Lock-Token")
+	var ms propMultistatus
+	if err := xml.NewDecoder(rs.Body).Decode(&ms); err == nil {
+		if len(ms.Response) > 0 && len(ms.Response[0].PropStat) > 0 {
+			if al := ms.Response[0].PropStat[0].Prop.LockDiscovery.ActiveLock; len(al) > 0 {
+				if token == "" {
+					token = al[0].LockToken.Href
+				}
+			}
+		}
+	}
+	if token == "" {
+		return LockToken{}, fmt.Errorf("gowebdav: LOCK %s: no Lock-Token returned", path)
+	}
+
+	lt := LockToken{Token: trimAngle(token), Root: path, Timeout: opts.Timeout}
+
+	if c.lockSystem == nil {
+		c.lockSystem = NewMemLS()
+	}
+	c.lockSystem.Put(path, lt)
+
+	return lt, nil
+}
+
+// Unlock releases a previously acquired lock.
+func (c *Client) Unlock(path string, token LockToken) error {
+	rs, err := c.req("UNLOCK", path, nil, func(r *http.Request) {
+		r.Header.Set("Lock-Token", fmt.Sprintf("<%s>", token.Token))
+	})
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return &StatusError{Status: rs.StatusCode}
+	}
+
+	if c.lockSystem != nil {
+		c.lockSystem.Remove(path)
+	}
+	return nil
+}
+
+// Refresh re-sends LOCK with an "If" header and no body to extend the
+// timeout of an existing lock, returning the same token with its Timeout
+// set to timeout.
+func (c *Client) Refresh(path string, token LockToken, timeout time.Duration) (LockToken, error) {
+	var timeoutHeader string
+	if timeout > 0 {
+		timeoutHeader = fmt.Sprintf("Second-%d", int(timeout.Seconds()))
+	} else {
+		timeoutHeader = "Infinite"
+	}
+
+	rs, err := c.req("LOCK", path, nil, func(r *http.Request) {
+		r.Header.Set("If", fmt.Sprintf("(<%s>)", token.Token))
+		r.Header.Set("Timeout", timeoutHeader)
+	})
+	if err != nil {
+		return LockToken{}, err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return LockToken{}, &StatusError{Status: rs.StatusCode}
+	}
+
+	lt := LockToken{Token: token.Token, Root: path, Timeout: timeout}
+	if c.lockSystem != nil {
+		c.lockSystem.Put(path, lt)
+	}
+	return lt, nil
+}
+
+func trimAngle(s string) string {
+	if len(s) >= 2 && s[0] == '<' && s[len(s)-1] == '>' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package gowebdav
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	h := &webdav.Handler{
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLockThenSecondLockIs423(t *testing.T) {
+	srv := newTestServer(t)
+
+	a := NewClient(srv.URL, "", "")
+	if err := a.Write("/foo.txt", []byte("hello"), 0644); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	token, err := a.Lock("/foo.txt", LockOptions{Scope: ExclusiveLock, Owner: "a"})
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if token.Token == "" {
+		t.Fatal("Lock returned an empty token")
+	}
+
+	b := NewClient(srv.URL, "", "")
+	if _, err := b.Lock("/foo.txt", LockOptions{Scope: ExclusiveLock, Owner: "b"}); err == nil {
+		t.Fatal("expected second exclusive Lock to fail while the first is held")
+	} else if se, ok := err.(*StatusError); !ok || se.Status != http.StatusLocked {
+		t.Fatalf("expected a 423 StatusError, got %v (%T)", err, err)
+	}
+}
+
+func TestLockAutomaticallyInjectsIfHeader(t *testing.T) {
+	srv := newTestServer(t)
+
+	owner := NewClient(srv.URL, "", "")
+	if err := owner.Write("/foo.txt", []byte("hello"), 0644); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := owner.Lock("/foo.txt", LockOptions{Scope: ExclusiveLock, Owner: "owner"}); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+
+	// The owning Client should carry its own lock token automatically.
+	if err := owner.Write("/foo.txt", []byte("updated"), 0644); err != nil {
+		t.Fatalf("Write from lock owner should succeed via automatic If header, got: %v", err)
+	}
+
+	// A Client with no knowledge of the token should be rejected.
+	stranger := NewClient(srv.URL, "", "")
+	if err := stranger.Write("/foo.txt", []byte("should not land"), 0644); err == nil {
+		t.Fatal("expected Write without the lock token to fail")
+	} else if se, ok := err.(*StatusError); !ok || se.Status != http.StatusLocked {
+		t.Fatalf("expected a 423 StatusError, got %v (%T)", err, err)
+	}
+}
+
+func TestUnlockThenWriteSucceeds(t *testing.T) {
+	srv := newTestServer(t)
+
+	c := NewClient(srv.URL, "", "")
+	if err := c.Write("/foo.txt", []byte("hello"), 0644); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	token, err := c.Lock("/foo.txt", LockOptions{Scope: ExclusiveLock, Owner: "c"})
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if err := c.Unlock("/foo.txt", token); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	other := NewClient(srv.URL, "", "")
+	if err := other.Write("/foo.txt", []byte("now anyone can write"), 0644); err != nil {
+		t.Fatalf("Write after Unlock should succeed, got: %v", err)
+	}
+}
+
+func TestRefreshExtendsLock(t *testing.T) {
+	srv := newTestServer(t)
+
+	c := NewClient(srv.URL, "", "")
+	if err := c.Write("/foo.txt", []byte("hello"), 0644); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	token, err := c.Lock("/foo.txt", LockOptions{Scope: ExclusiveLock, Owner: "c", Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+
+	refreshed, err := c.Refresh("/foo.txt", token, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if refreshed.Token != token.Token {
+		t.Fatalf("Refresh changed the token: got %q, want %q", refreshed.Token, token.Token)
+	}
+
+	// The refreshed token should still authorize writes for its owner.
+	if err := c.Write("/foo.txt", []byte("still mine"), 0644); err != nil {
+		t.Fatalf("Write after Refresh should succeed, got: %v", err)
+	}
+}
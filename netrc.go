@@ -0,0 +1,65 @@
+package gowebdav
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// ReadConfig reads the login/password for uri's host out of a netrc-style
+// file, returning two empty strings if the file is missing or has no
+// matching machine entry.
+func ReadConfig(uri, netrcFile string) (user, pw string) {
+	f, err := os.Open(netrcFile)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+
+	host := hostOf(uri)
+
+	var login, password string
+	matched := false
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		for i := 0; i < len(fields); i++ {
+			switch fields[i] {
+			case "machine":
+				if matched {
+					return login, password
+				}
+				if i+1 < len(fields) {
+					matched = fields[i+1] == host
+					login, password = "", ""
+				}
+				i++
+			case "login":
+				if matched && i+1 < len(fields) {
+					login = fields[i+1]
+				}
+				i++
+			case "password":
+				if matched && i+1 < len(fields) {
+					password = fields[i+1]
+				}
+				i++
+			}
+		}
+	}
+
+	if matched {
+		return login, password
+	}
+	return "", ""
+}
+
+func hostOf(uri string) string {
+	s := strings.TrimPrefix(uri, "https://")
+	s = strings.TrimPrefix(s, "http://")
+	if i := strings.IndexAny(s, "/:"); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
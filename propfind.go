@@ -0,0 +1,253 @@
+package gowebdav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// escapeXML escapes s for safe use as XML text/attribute content, so
+// caller-supplied namespaces, local names and property values can't break
+// out of the surrounding element or inject extra ones.
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// Depth is the value of the WebDAV "Depth" header.
+type Depth string
+
+const (
+	Depth0        Depth = "0"
+	Depth1        Depth = "1"
+	DepthInfinity Depth = "infinity"
+)
+
+// PropName identifies a WebDAV property by its XML namespace and local
+// name, e.g. {"DAV:", "quota-available-bytes"} or
+// {"http://owncloud.org/ns", "fileid"}.
+type PropName struct {
+	Namespace string
+	Local     string
+}
+
+func (p PropName) String() string {
+	return "{" + p.Namespace + "}" + p.Local
+}
+
+// RawXML is the undecoded inner XML of a single property value, left for
+// the caller to unmarshal into whatever shape that property needs (plain
+// text, a nested struct, a CalDAV/CardDAV fragment, ...).
+type RawXML []byte
+
+func (r RawXML) String() string {
+	return string(r)
+}
+
+// Resource is one <D:response> entry returned by PropFind: the href it
+// describes plus every requested property that the server returned,
+// keyed by its resolved PropName.
+type Resource struct {
+	Path  string
+	Props map[PropName]RawXML
+}
+
+// Prop is a property name/value pair for use with PropPatch.
+type Prop struct {
+	Name  PropName
+	Value string // text to set; PropPatch XML-escapes it before sending
+}
+
+// Client.PropFind lets callers ask for any set of properties, not just the
+// handful ReadDir/Stat hardcode. It builds the request body from props,
+// issues PROPFIND and decodes the multistatus response into Resources
+// whose Props map is keyed by the resolved (namespace, local) pair so
+// callers can pull e.g. {DAV:}quota-used-bytes or
+// {http://owncloud.org/ns}fileid.
+func (c *Client) PropFind(path string, depth Depth, props []PropName) ([]Resource, error) {
+	body, nsPrefix := buildPropfindBody(props)
+
+	rs, err := c.req("PROPFIND", path, strings.NewReader(body), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/xml;charset=UTF-8")
+		r.Header.Set("Depth", string(depth))
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return nil, &StatusError{Status: rs.StatusCode}
+	}
+
+	return decodeMultistatus(rs.Body, nsPrefix)
+}
+
+func buildPropfindBody(props []PropName) (string, map[string]string) {
+	nsPrefix := map[string]string{"DAV:": "D"}
+	next := 0
+	prefixFor := func(ns string) string {
+		if p, ok := nsPrefix[ns]; ok {
+			return p
+		}
+		next++
+		p := fmt.Sprintf("ns%d", next)
+		nsPrefix[ns] = p
+		return p
+	}
+
+	var propsXML strings.Builder
+	for _, p := range props {
+		prefixFor(p.Namespace)
+		propsXML.WriteString(fmt.Sprintf("<%s:%s/>", nsPrefix[p.Namespace], escapeXML(p.Local)))
+	}
+
+	var xmlnsAttrs strings.Builder
+	for ns, prefix := range nsPrefix {
+		fmt.Fprintf(&xmlnsAttrs, ` xmlns:%s="%s"`, prefix, escapeXML(ns))
+	}
+
+	body := fmt.Sprintf(`<D:propfind%s><D:prop>%s</D:prop></D:propfind>`, xmlnsAttrs.String(), propsXML.String())
+	return body, nsPrefix
+}
+
+// rawProp captures one child element of <prop> without knowing its name
+// ahead of time, keeping both its resolved namespace and its raw inner
+// XML.
+type rawProp struct {
+	XMLName xml.Name
+	Inner   []byte `xml:",innerxml"`
+}
+
+type multistatusXML struct {
+	Response []struct {
+		Href     string `xml:"href"`
+		PropStat []struct {
+			Prop struct {
+				Raw []rawProp `xml:",any"`
+			} `xml:"prop"`
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+func decodeMultistatus(r io.Reader, nsPrefix map[string]string) ([]Resource, error) {
+	var ms multistatusXML
+	if err := xml.NewDecoder(r).Decode(&ms); err != nil {
+		return nil, err
+	}
+
+	out := make([]Resource, 0, len(ms.Response))
+	for _, resp := range ms.Response {
+		res := Resource{Path: resp.Href, Props: map[PropName]RawXML{}}
+		for _, ps := range resp.PropStat {
+			if !strings.Contains(ps.Status, "200") {
+				continue
+			}
+			for _, p := range ps.Prop.Raw {
+				name := PropName{Namespace: p.XMLName.Space, Local: p.XMLName.Local}
+				res.Props[name] = RawXML(p.Inner)
+			}
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// PropPatch issues a PROPPATCH setting every Prop in set and removing
+// every PropName in remove.
+func (c *Client) PropPatch(path string, set []Prop, remove []PropName) error {
+	nsPrefix := map[string]string{"DAV:": "D"}
+	next := 0
+	prefixFor := func(ns string) string {
+		if p, ok := nsPrefix[ns]; ok {
+			return p
+		}
+		next++
+		p := fmt.Sprintf("ns%d", next)
+		nsPrefix[ns] = p
+		return p
+	}
+
+	var sb strings.Builder
+	if len(set) > 0 {
+		sb.WriteString("<D:set><D:prop>")
+		for _, p := range set {
+			prefixFor(p.Name.Namespace)
+			prefix, local := nsPrefix[p.Name.Namespace], escapeXML(p.Name.Local)
+			fmt.Fprintf(&sb, "<%s:%s>%s</%s:%s>", prefix, local, escapeXML(p.Value), prefix, local)
+		}
+		sb.WriteString("</D:prop></D:set>")
+	}
+	if len(remove) > 0 {
+		sb.WriteString("<D:remove><D:prop>")
+		for _, n := range remove {
+			prefixFor(n.Namespace)
+			fmt.Fprintf(&sb, "<%s:%s/>", nsPrefix[n.Namespace], escapeXML(n.Local))
+		}
+		sb.WriteString("</D:prop></D:remove>")
+	}
+
+	var xmlnsAttrs strings.Builder
+	for ns, prefix := range nsPrefix {
+		fmt.Fprintf(&xmlnsAttrs, ` xmlns:%s="%s"`, prefix, escapeXML(ns))
+	}
+
+	body := fmt.Sprintf(`<D:propertyupdate%s>%s</D:propertyupdate>`, xmlnsAttrs.String(), sb.String())
+
+	rs, err := c.req("PROPPATCH", path, bytes.NewReader([]byte(body)), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/xml;charset=UTF-8")
+	})
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode >= 400 {
+		return &StatusError{Status: rs.StatusCode}
+	}
+
+	return decodePropstatErrors(rs.Body)
+}
+
+// decodePropstatErrors reports the first non-200 <D:status> found in a
+// PROPPATCH multistatus response body, naming the properties it applies
+// to. A 207 where every propstat landed on 200 - the common success case
+// - decodes cleanly and returns nil; a body that isn't multistatus XML
+// at all (some servers don't bother on success) is not itself an error.
+func decodePropstatErrors(r io.Reader) error {
+	var ms multistatusXML
+	if err := xml.NewDecoder(r).Decode(&ms); err != nil {
+		return nil
+	}
+
+	for _, resp := range ms.Response {
+		for _, ps := range resp.PropStat {
+			if strings.Contains(ps.Status, "200") {
+				continue
+			}
+			names := make([]string, 0, len(ps.Prop.Raw))
+			for _, p := range ps.Prop.Raw {
+				names = append(names, PropName{Namespace: p.XMLName.Space, Local: p.XMLName.Local}.String())
+			}
+			return fmt.Errorf("gowebdav: PROPPATCH %s: %s failed: %s", resp.Href, names, ps.Status)
+		}
+	}
+	return nil
+}
+
+// QuotaProps is the common pair of quota properties servers advertise
+// under the DAV: namespace, handy as an argument to PropFind.
+var QuotaProps = []PropName{
+	{Namespace: "DAV:", Local: "quota-available-bytes"},
+	{Namespace: "DAV:", Local: "quota-used-bytes"},
+}
+
+// GetETagProp is DAV:getetag, also consulted by ReadDir/Stat so its value
+// can be surfaced through the etager interface the sync subsystem uses
+// for change detection.
+var GetETagProp = PropName{Namespace: "DAV:", Local: "getetag"}
@@ -0,0 +1,60 @@
+package gowebdav
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPropFindReturnsStatusErrorOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "")
+	_, err := c.PropFind("/foo", Depth0, QuotaProps)
+	if se, ok := err.(*StatusError); !ok || se.Status != http.StatusForbidden {
+		t.Fatalf("expected a 403 StatusError, got %v (%T)", err, err)
+	}
+}
+
+func TestPropPatchReturnsStatusErrorOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "")
+	err := c.PropPatch("/foo", []Prop{{Name: GetETagProp, Value: "x"}}, nil)
+	if se, ok := err.(*StatusError); !ok || se.Status != http.StatusForbidden {
+		t.Fatalf("expected a 403 StatusError, got %v (%T)", err, err)
+	}
+}
+
+func TestPropPatchReportsPerPropertyFailureIn207(t *testing.T) {
+	const body = `<?xml version="1.0"?>
+<D:multistatus xmlns:D="DAV:">
+  <D:response>
+    <D:href>/foo</D:href>
+    <D:propstat>
+      <D:prop><D:quota-used-bytes/></D:prop>
+      <D:status>HTTP/1.1 409 Conflict</D:status>
+    </D:propstat>
+  </D:response>
+</D:multistatus>`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "")
+	err := c.PropPatch("/foo", []Prop{{Name: QuotaProps[1], Value: "1"}}, nil)
+	if err == nil {
+		t.Fatal("expected an error for the 409 propstat, got nil")
+	}
+}
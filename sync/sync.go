@@ -0,0 +1,580 @@
+// Package sync mirrors a local directory tree and a remote WebDAV tree,
+// the "rclone-lite" use case the gowebdav CLI keeps getting asked for.
+package sync
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	d "github.com/mcmap4/gowebdav"
+)
+
+// ConflictPolicy decides what happens when both sides changed the same
+// path between two runs of Mirror.
+type ConflictPolicy int
+
+const (
+	// NewerWins keeps whichever side has the more recent modtime.
+	NewerWins ConflictPolicy = iota
+	// LocalWins always keeps the local copy.
+	LocalWins
+	// RemoteWins always keeps the remote copy.
+	RemoteWins
+	// Rename keeps both, renaming the remote copy with a ".conflict" suffix.
+	Rename
+)
+
+// EventKind classifies an Event sent on Syncer.Events.
+type EventKind int
+
+const (
+	EventCopied EventKind = iota
+	EventDeleted
+	EventSkipped
+	EventConflict
+)
+
+// Event reports progress for a single path during Push, Pull or Mirror.
+type Event struct {
+	Kind EventKind
+	Path string
+	Err  error
+}
+
+// Syncer walks a local root and a remote root and transfers the delta
+// between them.
+type Syncer struct {
+	Client *d.Client
+	Local  string
+	Remote string
+
+	// Conflict picks the resolution strategy used by Mirror. Defaults to
+	// NewerWins.
+	Conflict ConflictPolicy
+	// DryRun reports what would change without touching either side.
+	DryRun bool
+	// Concurrency bounds the number of workers comparing/transferring
+	// files at once. Defaults to 4.
+	Concurrency int
+	// Journal is the path to a JSON file caching the last-known remote
+	// state, used to tell deletions from new files. Defaults to
+	// "<Local>/.gowebdav.journal".
+	Journal string
+
+	// Events, if non-nil, receives one Event per processed path. The
+	// caller is responsible for draining it; Syncer buffers sends so a
+	// slow consumer cannot deadlock a sync.
+	Events chan Event
+
+	ignore []string
+}
+
+// entry is the per-path state cached in the journal between runs.
+type entry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	ETag    string    `json:"etag,omitempty"`
+}
+
+type journal map[string]entry
+
+// NewSyncer builds a Syncer for the given client and local/remote roots.
+func NewSyncer(c *d.Client, local, remote string) *Syncer {
+	return &Syncer{
+		Client:      c,
+		Local:       local,
+		Remote:      remote,
+		Concurrency: 4,
+		Journal:     filepath.Join(local, ".gowebdav.journal"),
+	}
+}
+
+// LoadIgnore reads glob patterns (one per line, "#" comments allowed) from
+// a .gowebdavignore file, e.g. the one found at the root of Local.
+func (s *Syncer) LoadIgnore(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		s.ignore = append(s.ignore, line)
+	}
+	return sc.Err()
+}
+
+func (s *Syncer) ignored(rel string) bool {
+	for _, pat := range s.ignore {
+		if ok, _ := filepath.Match(pat, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pat, filepath.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Syncer) emit(ev Event) {
+	if s.Events == nil {
+		return
+	}
+	select {
+	case s.Events <- ev:
+	default:
+		go func() { s.Events <- ev }()
+	}
+}
+
+// Push uploads every local file that is missing remotely or differs from
+// its remote counterpart (see sameVersion), and removes remote files that vanished locally since the
+// last run recorded in the journal.
+func (s *Syncer) Push(ctx context.Context) error {
+	localState, err := s.scanLocal()
+	if err != nil {
+		return err
+	}
+	prev, _ := s.loadJournal()
+
+	g := newGroup(s.concurrency())
+	for rel, st := range localState {
+		rel, st := rel, st
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.pushOne(rel, st)
+		})
+	}
+	for rel := range prev {
+		if _, ok := localState[rel]; ok {
+			continue
+		}
+		rel := rel
+		g.Go(func() error {
+			if s.DryRun {
+				s.emit(Event{Kind: EventDeleted, Path: rel})
+				return nil
+			}
+			err := s.Client.Remove(path.Join(s.Remote, rel))
+			s.emit(Event{Kind: EventDeleted, Path: rel, Err: err})
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return s.saveJournal(localState)
+}
+
+func (s *Syncer) pushOne(rel string, st entry) error {
+	remotePath := path.Join(s.Remote, rel)
+	ri, err := s.Client.Stat(remotePath)
+	if err == nil && sameVersion(st, statEntry(ri)) {
+		s.emit(Event{Kind: EventSkipped, Path: rel})
+		return nil
+	}
+
+	if s.DryRun {
+		s.emit(Event{Kind: EventCopied, Path: rel})
+		return nil
+	}
+
+	f, err := os.Open(filepath.Join(s.Local, rel))
+	if err != nil {
+		s.emit(Event{Kind: EventSkipped, Path: rel, Err: err})
+		return nil
+	}
+	defer f.Close()
+
+	if err := s.Client.MkdirAll(path.Dir(remotePath), 0755); err != nil {
+		s.emit(Event{Kind: EventSkipped, Path: rel, Err: err})
+		return nil
+	}
+
+	err = s.Client.WriteStream(remotePath, f, 0644)
+	s.emit(Event{Kind: EventCopied, Path: rel, Err: err})
+	return err
+}
+
+// Pull downloads every remote file that is missing locally or differs from
+// its local counterpart (see sameVersion), and removes local files that vanished remotely since the
+// last run recorded in the journal.
+func (s *Syncer) Pull(ctx context.Context) error {
+	remoteState, err := s.scanRemote()
+	if err != nil {
+		return err
+	}
+	prev, _ := s.loadJournal()
+
+	g := newGroup(s.concurrency())
+	for rel, st := range remoteState {
+		rel, st := rel, st
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.pullOne(rel, st)
+		})
+	}
+	for rel := range prev {
+		if _, ok := remoteState[rel]; ok {
+			continue
+		}
+		rel := rel
+		g.Go(func() error {
+			local := filepath.Join(s.Local, rel)
+			if s.DryRun {
+				s.emit(Event{Kind: EventDeleted, Path: rel})
+				return nil
+			}
+			err := os.Remove(local)
+			if err != nil && os.IsNotExist(err) {
+				err = nil
+			}
+			s.emit(Event{Kind: EventDeleted, Path: rel, Err: err})
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return s.saveJournal(remoteState)
+}
+
+func (s *Syncer) pullOne(rel string, st entry) error {
+	local := filepath.Join(s.Local, rel)
+	if fi, err := os.Stat(local); err == nil && sameVersion(st, localEntry(fi, "")) {
+		s.emit(Event{Kind: EventSkipped, Path: rel})
+		return nil
+	}
+
+	if s.DryRun {
+		s.emit(Event{Kind: EventCopied, Path: rel})
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+		s.emit(Event{Kind: EventSkipped, Path: rel, Err: err})
+		return nil
+	}
+
+	rc, err := s.Client.ReadStream(path.Join(s.Remote, rel))
+	if err != nil {
+		s.emit(Event{Kind: EventSkipped, Path: rel, Err: err})
+		return nil
+	}
+	defer rc.Close()
+
+	f, err := os.Create(local)
+	if err != nil {
+		s.emit(Event{Kind: EventSkipped, Path: rel, Err: err})
+		return nil
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, rc)
+	s.emit(Event{Kind: EventCopied, Path: rel, Err: err})
+	return err
+}
+
+// Mirror synchronizes both directions, resolving paths that changed on
+// both sides since the last run using Conflict.
+func (s *Syncer) Mirror(ctx context.Context) error {
+	localState, err := s.scanLocal()
+	if err != nil {
+		return err
+	}
+	remoteState, err := s.scanRemote()
+	if err != nil {
+		return err
+	}
+	prev, _ := s.loadJournal()
+
+	all := make(map[string]struct{})
+	for rel := range localState {
+		all[rel] = struct{}{}
+	}
+	for rel := range remoteState {
+		all[rel] = struct{}{}
+	}
+	for rel := range prev {
+		all[rel] = struct{}{}
+	}
+
+	g := newGroup(s.concurrency())
+	for rel := range all {
+		rel := rel
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.mirrorOne(rel, localState[rel], remoteState[rel], prev[rel])
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	merged, err := s.scanLocal()
+	if err != nil {
+		return err
+	}
+	return s.saveJournal(merged)
+}
+
+func (s *Syncer) mirrorOne(rel string, local, remote, base entry) error {
+	localExists := local != (entry{})
+	remoteExists := remote != (entry{})
+	baseExists := base != (entry{})
+
+	switch {
+	case localExists && remoteExists:
+		if sameVersion(local, remote) {
+			s.emit(Event{Kind: EventSkipped, Path: rel})
+			return nil
+		}
+		if baseExists && sameVersion(local, base) {
+			return s.pullOne(rel, remote)
+		}
+		if baseExists && sameVersion(remote, base) {
+			return s.pushOne(rel, local)
+		}
+		return s.resolveConflict(rel, local, remote)
+
+	case localExists && !remoteExists:
+		if baseExists {
+			// remote side deleted it; honor the deletion locally.
+			if s.DryRun {
+				s.emit(Event{Kind: EventDeleted, Path: rel})
+				return nil
+			}
+			err := os.Remove(filepath.Join(s.Local, rel))
+			s.emit(Event{Kind: EventDeleted, Path: rel, Err: err})
+			return nil
+		}
+		return s.pushOne(rel, local)
+
+	case !localExists && remoteExists:
+		if baseExists {
+			if s.DryRun {
+				s.emit(Event{Kind: EventDeleted, Path: rel})
+				return nil
+			}
+			err := s.Client.Remove(path.Join(s.Remote, rel))
+			s.emit(Event{Kind: EventDeleted, Path: rel, Err: err})
+			return nil
+		}
+		return s.pullOne(rel, remote)
+	}
+	return nil
+}
+
+func (s *Syncer) resolveConflict(rel string, local, remote entry) error {
+	switch s.Conflict {
+	case LocalWins:
+		return s.pushOne(rel, local)
+	case RemoteWins:
+		return s.pullOne(rel, remote)
+	case Rename:
+		if s.DryRun {
+			s.emit(Event{Kind: EventConflict, Path: rel})
+			return nil
+		}
+		renamed := rel + ".conflict"
+		rc, err := s.Client.ReadStream(path.Join(s.Remote, rel))
+		if err != nil {
+			s.emit(Event{Kind: EventConflict, Path: rel, Err: err})
+			return nil
+		}
+		defer rc.Close()
+		err = s.Client.WriteStream(path.Join(s.Remote, renamed), rc, 0644)
+		s.emit(Event{Kind: EventConflict, Path: rel, Err: err})
+		if err != nil {
+			return err
+		}
+		return s.pushOne(rel, local)
+	default: // NewerWins
+		if local.ModTime.After(remote.ModTime) {
+			return s.pushOne(rel, local)
+		}
+		return s.pullOne(rel, remote)
+	}
+}
+
+func sameVersion(a, b entry) bool {
+	if a.ETag != "" && b.ETag != "" {
+		return a.ETag == b.ETag
+	}
+	return a.Size == b.Size && a.ModTime.Equal(b.ModTime)
+}
+
+func (s *Syncer) scanLocal() (journal, error) {
+	out := make(journal)
+	err := filepath.WalkDir(s.Local, func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Local, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == filepath.Base(s.Journal) || s.ignored(rel) {
+			return nil
+		}
+		fi, err := de.Info()
+		if err != nil {
+			return err
+		}
+		out[rel] = localEntry(fi, "")
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.SkipDir) {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Syncer) scanRemote() (journal, error) {
+	out := make(journal)
+	var walk func(rel string) error
+	walk = func(rel string) error {
+		files, err := s.Client.ReadDir(path.Join(s.Remote, rel))
+		if err != nil {
+			return err
+		}
+		for _, fi := range files {
+			childRel := path.Join(rel, fi.Name())
+			if fi.IsDir() {
+				if err := walk(childRel); err != nil {
+					return err
+				}
+				continue
+			}
+			if s.ignored(childRel) {
+				continue
+			}
+			out[childRel] = statEntry(fi)
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// etager is implemented by the gowebdav os.FileInfo returned from
+// ReadDir/Stat; its ETag is empty when the server reported no DAV:getetag.
+type etager interface {
+	ETag() string
+}
+
+func statEntry(fi os.FileInfo) entry {
+	e := localEntry(fi, "")
+	if et, ok := fi.(etager); ok {
+		e.ETag = et.ETag()
+	}
+	return e
+}
+
+func localEntry(fi os.FileInfo, etag string) entry {
+	return entry{Size: fi.Size(), ModTime: fi.ModTime(), ETag: etag}
+}
+
+func (s *Syncer) loadJournal() (journal, error) {
+	f, err := os.Open(s.Journal)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return journal{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var j journal
+	if err := json.NewDecoder(f).Decode(&j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (s *Syncer) saveJournal(j journal) error {
+	if s.DryRun {
+		return nil
+	}
+	f, err := os.Create(s.Journal)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(j)
+}
+
+func (s *Syncer) concurrency() int {
+	if s.Concurrency > 0 {
+		return s.Concurrency
+	}
+	return 4
+}
+
+// group runs a bounded number of fallible tasks concurrently and collects
+// every error; Wait returns the one whose message sorts first. Similar in
+// spirit to golang.org/x/sync/errgroup but without the extra dependency.
+type group struct {
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+func newGroup(n int) *group {
+	return &group{sem: make(chan struct{}, n)}
+}
+
+func (g *group) Go(fn func() error) {
+	g.wg.Add(1)
+	g.sem <- struct{}{}
+	go func() {
+		defer g.wg.Done()
+		defer func() { <-g.sem }()
+		if err := fn(); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func (g *group) Wait() error {
+	g.wg.Wait()
+	if len(g.errs) == 0 {
+		return nil
+	}
+	sort.Slice(g.errs, func(i, j int) bool { return g.errs[i].Error() < g.errs[j].Error() })
+	return g.errs[0]
+}
@@ -0,0 +1,47 @@
+package sync
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// fakeFileInfo implements os.FileInfo plus the unexported etager
+// interface, standing in for *gowebdav.File (which now implements ETag()
+// too) so statEntry's ETag pickup can be exercised without a live server.
+type fakeFileInfo struct {
+	size int64
+	mod  time.Time
+	etag string
+}
+
+func (f fakeFileInfo) Name() string       { return "f" }
+func (f fakeFileInfo) Size() int64        { return f.size }
+func (f fakeFileInfo) Mode() os.FileMode  { return 0644 }
+func (f fakeFileInfo) ModTime() time.Time { return f.mod }
+func (f fakeFileInfo) IsDir() bool        { return false }
+func (f fakeFileInfo) Sys() interface{}   { return nil }
+func (f fakeFileInfo) ETag() string       { return f.etag }
+
+func TestStatEntryPrefersETag(t *testing.T) {
+	fi := fakeFileInfo{size: 10, mod: time.Now(), etag: `"abc123"`}
+
+	e := statEntry(fi)
+	if e.ETag != `"abc123"` {
+		t.Fatalf("statEntry did not pick up ETag from the etager interface: got %q", e.ETag)
+	}
+}
+
+func TestSameVersionPrefersETagOverSizeAndModTime(t *testing.T) {
+	a := entry{Size: 1, ModTime: time.Unix(0, 0), ETag: "same"}
+	b := entry{Size: 999, ModTime: time.Unix(100, 0), ETag: "same"}
+
+	if !sameVersion(a, b) {
+		t.Fatal("entries sharing an ETag should compare equal regardless of size/modtime")
+	}
+
+	c := entry{Size: 1, ModTime: time.Unix(0, 0), ETag: "different"}
+	if sameVersion(a, c) {
+		t.Fatal("entries with different ETags should not compare equal")
+	}
+}